@@ -0,0 +1,88 @@
+// Package dedup detects content-duplicate pages so a crawl can collapse
+// mirrored URLs (session IDs, tracking params, trailing slashes) into a
+// single canonical entry instead of emitting every variant.
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Tracker records which URL was first seen for each content fingerprint,
+// so later URLs that hash to the same fingerprint can be recognized as
+// aliases of it.
+type Tracker struct {
+	seen sync.Map // fingerprint (string) -> canonical URL (string)
+}
+
+// New returns an empty Tracker.
+func New() *Tracker {
+	return &Tracker{}
+}
+
+// Register records urlStr as having produced fingerprint and reports
+// the canonical URL for that fingerprint: urlStr itself if this is the
+// first time the fingerprint has been seen, or the URL that was first
+// registered for it otherwise. isDuplicate is true when urlStr is not
+// the canonical URL.
+func (t *Tracker) Register(fingerprint, urlStr string) (canonical string, isDuplicate bool) {
+	actual, loaded := t.seen.LoadOrStore(fingerprint, urlStr)
+	canonical = actual.(string)
+	return canonical, loaded && canonical != urlStr
+}
+
+var (
+	scriptPattern     = regexp.MustCompile(`(?is)<script.*?</script>`)
+	commentPattern    = regexp.MustCompile(`(?s)<!--.*?-->`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Fingerprint computes a stable hash of an HTML document's content,
+// ignoring scripts, comments, and incidental whitespace differences, so
+// that pages differing only in those respects collapse to the same
+// fingerprint.
+func Fingerprint(body []byte) string {
+	normalized := scriptPattern.ReplaceAll(body, nil)
+	normalized = commentPattern.ReplaceAll(normalized, nil)
+	normalized = whitespacePattern.ReplaceAll(normalized, nil)
+
+	sum := sha256.Sum256(normalized)
+	return hex.EncodeToString(sum[:])
+}
+
+// Canonicalize normalizes urlStr by dropping the query parameters named
+// in stripParams (case-insensitive) and any trailing slash from its
+// path, so that otherwise-identical URLs differing only by a tracking
+// parameter or a trailing slash collapse to the same string. It returns
+// urlStr unchanged if it cannot be parsed as a URL.
+func Canonicalize(urlStr string, stripParams []string) string {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return urlStr
+	}
+
+	if len(stripParams) > 0 && u.RawQuery != "" {
+		strip := make(map[string]struct{}, len(stripParams))
+		for _, p := range stripParams {
+			strip[strings.ToLower(p)] = struct{}{}
+		}
+
+		q := u.Query()
+		for key := range q {
+			if _, drop := strip[strings.ToLower(key)]; drop {
+				q.Del(key)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	if u.Path != "/" {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+	}
+
+	return u.String()
+}
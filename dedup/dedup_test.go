@@ -0,0 +1,58 @@
+package dedup_test
+
+import (
+	"testing"
+
+	"sitemap/dedup"
+)
+
+func TestCanonicalizeStripsListedParamsAndTrailingSlash(t *testing.T) {
+	got := dedup.Canonicalize("https://example.com/page/?session=abc&ref=tw&keep=1", []string{"session", "ref"})
+	want := "https://example.com/page?keep=1"
+	if got != want {
+		t.Errorf("Canonicalize() = %q, want %q", got, want)
+	}
+}
+
+func TestCanonicalizeLeavesRootSlash(t *testing.T) {
+	got := dedup.Canonicalize("https://example.com/", nil)
+	want := "https://example.com/"
+	if got != want {
+		t.Errorf("Canonicalize() = %q, want %q", got, want)
+	}
+}
+
+func TestFingerprintIgnoresScriptsCommentsAndWhitespace(t *testing.T) {
+	a := dedup.Fingerprint([]byte(`<html><body>  <p>Hello</p>  <script>track()</script><!-- ad --></body></html>`))
+	b := dedup.Fingerprint([]byte(`<html><body><p>Hello</p></body></html>`))
+	if a != b {
+		t.Errorf("expected fingerprints to match after stripping scripts/comments/whitespace, got %q and %q", a, b)
+	}
+}
+
+func TestFingerprintDiffersForDifferentContent(t *testing.T) {
+	a := dedup.Fingerprint([]byte(`<p>Hello</p>`))
+	b := dedup.Fingerprint([]byte(`<p>Goodbye</p>`))
+	if a == b {
+		t.Errorf("expected different content to produce different fingerprints")
+	}
+}
+
+func TestTrackerRegisterDetectsDuplicates(t *testing.T) {
+	tracker := dedup.New()
+
+	canonical, isDup := tracker.Register("hash1", "https://example.com/a")
+	if isDup || canonical != "https://example.com/a" {
+		t.Errorf("expected the first URL for a fingerprint to be canonical, got canonical=%q isDup=%v", canonical, isDup)
+	}
+
+	canonical, isDup = tracker.Register("hash1", "https://example.com/b")
+	if !isDup || canonical != "https://example.com/a" {
+		t.Errorf("expected the second URL for the same fingerprint to be a duplicate of the first, got canonical=%q isDup=%v", canonical, isDup)
+	}
+
+	canonical, isDup = tracker.Register("hash2", "https://example.com/c")
+	if isDup || canonical != "https://example.com/c" {
+		t.Errorf("expected a distinct fingerprint to be its own canonical, got canonical=%q isDup=%v", canonical, isDup)
+	}
+}
@@ -2,45 +2,144 @@ package link
 
 import (
 	"io"
+	"regexp"
 	"strings"
 
 	"golang.org/x/net/html"
 )
 
+// LinkType classifies the role a discovered Link plays on a page.
+type LinkType string
+
+const (
+	// LinkTypePrimary marks navigational links, i.e. <a href="...">.
+	LinkTypePrimary LinkType = "primary"
+	// LinkTypeRelated marks asset references a page needs in order to
+	// render: stylesheets, scripts, images, iframes, and CSS url(...)
+	// references pulled from <style> blocks and style="" attributes.
+	LinkTypeRelated LinkType = "related"
+	// LinkTypeCanonical marks a <link rel="canonical" href="..."> hint:
+	// the page's author-declared preferred URL, used to collapse mirrored
+	// URLs that serve the same content.
+	LinkTypeCanonical LinkType = "canonical"
+)
+
 type Link struct {
 	Href string
 	Text string
+	Kind LinkType
+}
+
+// cssURLPattern matches url(...) references inside @import statements and
+// other CSS declarations, used to pull assets out of <style> blocks and
+// style="" attributes.
+var cssURLPattern = regexp.MustCompile(`(?:@import|:).*url\(["']?([^'"\)]+)["']?\)`)
+
+// assetAttrs maps element names to the attribute holding their reference
+// and the LinkType that reference should be tagged with. <link> is
+// handled separately by collectLinks since its rel attribute decides
+// between LinkTypeRelated and LinkTypeCanonical.
+var assetAttrs = map[string]struct {
+	attr string
+	kind LinkType
+}{
+	"a":      {"href", LinkTypePrimary},
+	"img":    {"src", LinkTypeRelated},
+	"script": {"src", LinkTypeRelated},
+	"iframe": {"src", LinkTypeRelated},
 }
 
 // Parse will take an HTML document (as an io.Reader) and will return
-// a slice of links parsed from it, or an error if parsing fails.
+// a slice of links parsed from it, or an error if parsing fails. Anchors
+// are tagged LinkTypePrimary; assets that make the page render (images,
+// scripts, stylesheets, iframes, and CSS url(...) references) are tagged
+// LinkTypeRelated.
 func Parse(r io.Reader) ([]Link, error) {
 	doc, err := html.Parse(r)
 	if err != nil {
 		return nil, err
 	}
 
-	nodes := linkNodes(doc)
-
 	var links []Link
-	for _, node := range nodes {
-		links = append(links, buildLink(node))
+	collectLinks(doc, &links)
+	return links, nil
+}
+
+// collectLinks performs a depth-first search over the node tree, gathering
+// anchors, tagged assets, and CSS url(...) references along the way.
+func collectLinks(n *html.Node, links *[]Link) {
+	if n.Type == html.ElementNode {
+		if n.Data == "link" {
+			if href := attrValue(n, "href"); href != "" {
+				kind := LinkTypeRelated
+				if strings.EqualFold(attrValue(n, "rel"), "canonical") {
+					kind = LinkTypeCanonical
+				}
+				*links = append(*links, Link{Href: href, Kind: kind})
+			}
+		} else if spec, ok := assetAttrs[n.Data]; ok {
+			l := buildLink(n, spec.attr, spec.kind)
+			if spec.kind == LinkTypePrimary || l.Href != "" {
+				*links = append(*links, l)
+			}
+		}
+
+		if n.Data == "style" {
+			*links = append(*links, cssLinks(rawText(n), LinkTypeRelated)...)
+		}
+
+		if style := attrValue(n, "style"); style != "" {
+			*links = append(*links, cssLinks(style, LinkTypeRelated)...)
+		}
 	}
 
-	return links, nil
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		collectLinks(c, links)
+	}
+}
+
+// buildLink extracts the reference attribute (and, for primary links, the
+// anchor text) from a node.
+func buildLink(n *html.Node, attr string, kind LinkType) Link {
+	link := Link{
+		Href: attrValue(n, attr),
+		Kind: kind,
+	}
+	if kind == LinkTypePrimary {
+		link.Text = extractText(n)
+	}
+	return link
 }
 
-// buildLink extracts the href and text from an <a> node
-func buildLink(n *html.Node) Link {
-	var link Link
+// cssLinks extracts url(...) references from a blob of CSS text.
+func cssLinks(css string, kind LinkType) []Link {
+	var links []Link
+	for _, match := range cssURLPattern.FindAllStringSubmatch(css, -1) {
+		links = append(links, Link{Href: match[1], Kind: kind})
+	}
+	return links
+}
+
+// attrValue returns the value of the named attribute on n, or "" if absent.
+func attrValue(n *html.Node, key string) string {
 	for _, attr := range n.Attr {
-		if attr.Key == "href" {
-			link.Href = attr.Val
-			break
+		if attr.Key == key {
+			return attr.Val
 		}
 	}
-	link.Text = extractText(n)
-	return link
+	return ""
+}
+
+// rawText concatenates the text node children of n, used to read the raw
+// CSS body of a <style> element.
+func rawText(n *html.Node) string {
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+		}
+	}
+	return sb.String()
 }
 
 // extractText recursively extracts all text content from a node and its children,
@@ -67,17 +166,3 @@ func extractText(n *html.Node) string {
 
 	return strings.Join(strings.Fields(sb.String()), " ")
 }
-
-// linkNodes performs a depth-first search to find all <a> nodes
-// within the given HTML node tree.
-func linkNodes(n *html.Node) []*html.Node {
-	if n.Type == html.ElementNode && n.Data == "a" {
-		return []*html.Node{n}
-	}
-
-	var nodes []*html.Node
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		nodes = append(nodes, linkNodes(c)...)
-	}
-	return nodes
-}
@@ -19,7 +19,7 @@ func TestParse(t *testing.T) {
 			name: "Single Simple Link",
 			html: `<html><body><a href="/page1">Link 1</a></body></html>`,
 			expectedLinks: []link.Link{
-				{Href: "/page1", Text: "Link 1"},
+				{Href: "/page1", Text: "Link 1", Kind: link.LinkTypePrimary},
 			},
 			expectError: false,
 		},
@@ -33,8 +33,8 @@ func TestParse(t *testing.T) {
 				  </body>
 				</html>`,
 			expectedLinks: []link.Link{
-				{Href: "/page1", Text: "Link 1"},
-				{Href: "https://example.com/page2", Text: "Link 2"},
+				{Href: "/page1", Text: "Link 1", Kind: link.LinkTypePrimary},
+				{Href: "https://example.com/page2", Text: "Link 2", Kind: link.LinkTypePrimary},
 			},
 			expectError: false,
 		},
@@ -47,7 +47,7 @@ func TestParse(t *testing.T) {
 				  </span> info!
 				</a>`,
 			expectedLinks: []link.Link{
-				{Href: "/nested", Text: "Click here for more info!"},
+				{Href: "/nested", Text: "Click here for more info!", Kind: link.LinkTypePrimary},
 			},
 			expectError: false,
 		},
@@ -61,7 +61,7 @@ func TestParse(t *testing.T) {
 			name: "Link with No Href",
 			html: `<a>No Href Here</a>`,
 			expectedLinks: []link.Link{
-				{Href: "", Text: "No Href Here"},
+				{Href: "", Text: "No Href Here", Kind: link.LinkTypePrimary},
 			},
 			expectError: false,
 		},
@@ -69,7 +69,7 @@ func TestParse(t *testing.T) {
 			name: "Link with Empty Href",
 			html: `<a href="">Empty Href</a>`,
 			expectedLinks: []link.Link{
-				{Href: "", Text: "Empty Href"},
+				{Href: "", Text: "Empty Href", Kind: link.LinkTypePrimary},
 			},
 			expectError: false,
 		},
@@ -79,7 +79,7 @@ func TestParse(t *testing.T) {
 				<a href="/real">Real Link</a>
 				<!-- <a href="/commented">Commented Link</a> -->`,
 			expectedLinks: []link.Link{
-				{Href: "/real", Text: "Real Link"},
+				{Href: "/real", Text: "Real Link", Kind: link.LinkTypePrimary},
 			},
 			expectError: false,
 		},
@@ -93,7 +93,7 @@ func TestParse(t *testing.T) {
 			name: "Text Normalization Edge Case",
 			html: `<a href="/space">  leading and trailing   <span> internal	tab </span> multiple   spaces </a>`,
 			expectedLinks: []link.Link{
-				{Href: "/space", Text: "leading and trailing internal tab multiple spaces"},
+				{Href: "/space", Text: "leading and trailing internal tab multiple spaces", Kind: link.LinkTypePrimary},
 			},
 			expectError: false,
 		},
@@ -107,7 +107,7 @@ func TestParse(t *testing.T) {
 			name: "Fragment Link Only",
 			html: `<a href="#section">Section</a>`,
 			expectedLinks: []link.Link{
-				{Href: "#section", Text: "Section"},
+				{Href: "#section", Text: "Section", Kind: link.LinkTypePrimary},
 			},
 			expectError: false,
 		},
@@ -115,7 +115,69 @@ func TestParse(t *testing.T) {
 			name: "Link with HTML entities in text",
 			html: `<a href="/entity">Ben & Jerry</a>`,
 			expectedLinks: []link.Link{
-				{Href: "/entity", Text: "Ben & Jerry"},
+				{Href: "/entity", Text: "Ben & Jerry", Kind: link.LinkTypePrimary},
+			},
+			expectError: false,
+		},
+		{
+			name: "Asset Tags Are Tagged Related",
+			html: `
+				<html><head>
+				  <link href="/styles.css">
+				  <script src="/app.js"></script>
+				</head><body>
+				  <img src="/logo.png">
+				  <iframe src="/embed.html"></iframe>
+				  <a href="/page">Page</a>
+				</body></html>`,
+			expectedLinks: []link.Link{
+				{Href: "/styles.css", Kind: link.LinkTypeRelated},
+				{Href: "/app.js", Kind: link.LinkTypeRelated},
+				{Href: "/logo.png", Kind: link.LinkTypeRelated},
+				{Href: "/embed.html", Kind: link.LinkTypeRelated},
+				{Href: "/page", Text: "Page", Kind: link.LinkTypePrimary},
+			},
+			expectError: false,
+		},
+		{
+			name: "Asset Tags Without a Reference Are Skipped",
+			html: `<img><script></script><a href="/page">Page</a>`,
+			expectedLinks: []link.Link{
+				{Href: "/page", Text: "Page", Kind: link.LinkTypePrimary},
+			},
+			expectError: false,
+		},
+		{
+			name: "CSS url() Extracted from Style Block and Attribute",
+			html: `
+				<html><head>
+				  <style>
+				    @import url("/fonts/base.css");
+				    body { background: url('/img/bg.png'); }
+				  </style>
+				</head><body>
+				  <div style="background-image: url(/img/hero.jpg);">Hero</div>
+				</body></html>`,
+			expectedLinks: []link.Link{
+				{Href: "/fonts/base.css", Kind: link.LinkTypeRelated},
+				{Href: "/img/bg.png", Kind: link.LinkTypeRelated},
+				{Href: "/img/hero.jpg", Kind: link.LinkTypeRelated},
+			},
+			expectError: false,
+		},
+		{
+			name: "Canonical Link Is Tagged Separately From Other Link Tags",
+			html: `
+				<html><head>
+				  <link rel="canonical" href="/page">
+				  <link rel="stylesheet" href="/styles.css">
+				</head><body>
+				  <a href="/page?session=abc">Page</a>
+				</body></html>`,
+			expectedLinks: []link.Link{
+				{Href: "/page", Kind: link.LinkTypeCanonical},
+				{Href: "/styles.css", Kind: link.LinkTypeRelated},
+				{Href: "/page?session=abc", Text: "Page", Kind: link.LinkTypePrimary},
 			},
 			expectError: false,
 		},
@@ -1,15 +1,25 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/xml"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"regexp"
+	"sitemap/dedup"
 	"sitemap/link"
+	"sitemap/politeness"
+	"sitemap/queue"
+	"sitemap/scope"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -19,13 +29,144 @@ import (
 // Define the XML structure for the sitemap
 const xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
 
-type loc struct {
-	Value string `xml:"loc"`
+// maxURLsPerSitemap and maxSitemapBytes are the sitemaps.org limits on a
+// single sitemap file: at most 50,000 URLs or 50 MiB uncompressed. With
+// --output-dir, a crawl that exceeds either is split across sitemap-N.xml
+// files referenced by a sitemap_index.xml; the single sitemap printed to
+// stdout is not split.
+const (
+	maxURLsPerSitemap = 50000
+	maxSitemapBytes   = 50 * 1024 * 1024
+)
+
+type urlEntry struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
 }
 
 type urlset struct {
-	Urls  []loc  `xml:"url"`
-	Xmlns string `xml:"xmlns,attr"`
+	Urls  []urlEntry `xml:"url"`
+	Xmlns string     `xml:"xmlns,attr"`
+}
+
+type sitemapIndexEntry struct {
+	Loc string `xml:"loc"`
+}
+
+type sitemapIndex struct {
+	Sitemaps []sitemapIndexEntry `xml:"sitemap"`
+	Xmlns    string              `xml:"xmlns,attr"`
+}
+
+// PageInfo describes a single crawled or discovered URL along with the
+// metadata used to render its <url> entry: Last-Modified as captured
+// from the response that served it (zero if never fetched, e.g. related
+// assets), and a ChangeFreq/Priority heuristic derived from crawl depth.
+type PageInfo struct {
+	URL        string
+	LastMod    time.Time
+	ChangeFreq string
+	Priority   float64
+}
+
+const defaultUserAgent = "sitemap-crawler/1.0"
+
+// newHTTPClient builds the shared client used for every crawl request,
+// with a Transport tuned to keep connections alive and reuse them across
+// the many requests made to the same small set of hosts.
+func newHTTPClient() *http.Client {
+	transport := &http.Transport{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &http.Client{
+		Timeout:   15 * time.Second,
+		Transport: transport,
+	}
+}
+
+// repeatedFlag collects the values of a flag that may be passed more
+// than once, such as --scope-allow.
+type repeatedFlag []string
+
+func (r *repeatedFlag) String() string {
+	return strings.Join(*r, ",")
+}
+
+func (r *repeatedFlag) Set(v string) error {
+	*r = append(*r, v)
+	return nil
+}
+
+// buildScope assembles the scope.Scope that bounds the crawl from the
+// start URL and the --same-registered-domain, --scope-prefix,
+// --scope-allow, and --scope-deny flags.
+func buildScope(startURL *url.URL, sameRegisteredDomain bool, pathPrefix string, allowPatterns, denyPatterns []string) (scope.Scope, error) {
+	var base scope.Scope
+	if sameRegisteredDomain {
+		rd, err := scope.NewSameRegisteredDomain(startURL)
+		if err != nil {
+			return nil, err
+		}
+		base = rd
+	} else {
+		base = scope.NewSameHost(startURL)
+	}
+
+	combined := scope.All{base}
+
+	if pathPrefix != "" {
+		combined = append(combined, &scope.PathPrefix{Prefix: pathPrefix})
+	}
+
+	if len(allowPatterns) > 0 {
+		allow, err := compileRegexes(allowPatterns)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --scope-allow pattern: %w", err)
+		}
+		combined = append(combined, &scope.RegexAllow{Patterns: allow})
+	}
+
+	if len(denyPatterns) > 0 {
+		deny, err := compileRegexes(denyPatterns)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --scope-deny pattern: %w", err)
+		}
+		combined = append(combined, &scope.RegexDeny{Patterns: deny})
+	}
+
+	return combined, nil
+}
+
+func compileRegexes(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// changeFreqAndPriority returns a changefreq/priority heuristic for a
+// page found at the given crawl depth: shallower pages are assumed to
+// change more often and matter more, so they get a higher priority.
+func changeFreqAndPriority(depth int) (string, float64) {
+	switch {
+	case depth <= 0:
+		return "daily", 1.0
+	case depth == 1:
+		return "weekly", 0.8
+	case depth == 2:
+		return "weekly", 0.6
+	default:
+		return "monthly", 0.4
+	}
 }
 
 var ignoredExtensions = map[string]struct{}{
@@ -42,6 +183,22 @@ func main() {
 	maxDepth := flag.Int("depth", 3, "The maximum depth to traverse.")
 	workersFlag := flag.Int("workers", 10, "Number of concurrent workers.")
 	statsFlag := flag.Bool("stats", false, "Show periodic crawling stats.")
+	includeAssetsFlag := flag.Bool("include-assets", false, "Emit related assets (images, scripts, stylesheets, iframes) in the sitemap without following them for crawling.")
+	queueDirFlag := flag.String("queue-dir", "", "Directory for an on-disk visit queue. If set, crawl state is persisted to disk instead of kept in memory, bounding RAM use on very large crawls.")
+	resumeFlag := flag.Bool("resume", false, "Resume a previous crawl from --queue-dir instead of starting fresh. Requires --queue-dir.")
+	userAgentFlag := flag.String("user-agent", defaultUserAgent, "User-Agent to send with requests and to match against robots.txt rules.")
+	minDelayFlag := flag.Duration("min-delay", 0, "Minimum delay between requests to the same host, used when robots.txt specifies no Crawl-delay.")
+	sameRegisteredDomainFlag := flag.Bool("same-registered-domain", false, "Scope the crawl to the start URL's registered domain (e.g. example.com) instead of just its exact host.")
+	scopePrefixFlag := flag.String("scope-prefix", "", "Restrict the crawl to URLs whose path starts with this prefix.")
+	var scopeAllowFlag repeatedFlag
+	flag.Var(&scopeAllowFlag, "scope-allow", "Regular expression a URL must match to be in scope. May be given multiple times; a URL matching any is allowed.")
+	var scopeDenyFlag repeatedFlag
+	flag.Var(&scopeDenyFlag, "scope-deny", "Regular expression that excludes a URL from scope. May be given multiple times. Evaluated after --scope-allow.")
+	outputDirFlag := flag.String("output-dir", "", "Directory to write sitemap-N.xml(.gz) files and a sitemap_index.xml into. If unset, a single sitemap is printed to stdout instead.")
+	gzipFlag := flag.Bool("gzip", false, "Gzip each sitemap file written to --output-dir.")
+	baseURLFlag := flag.String("base-url", "", "Base URL used to build the <loc> entries in sitemap_index.xml. Required when --output-dir is set.")
+	var stripParamsFlag repeatedFlag
+	flag.Var(&stripParamsFlag, "strip-params", "Query parameter to drop when canonicalizing URLs (e.g. session IDs, tracking params). May be given multiple times.")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: go run main.go --url <your-starting-url> [options]\n")
@@ -57,17 +214,46 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *resumeFlag && *queueDirFlag == "" {
+		fmt.Fprintln(os.Stderr, "Error: --resume requires --queue-dir.")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if *outputDirFlag != "" && *baseURLFlag == "" {
+		fmt.Fprintln(os.Stderr, "Error: --output-dir requires --base-url.")
+		flag.Usage()
+		os.Exit(1)
+	}
+
 	log.SetOutput(os.Stderr)
 
+	startURL, err := url.Parse(*urlFlag)
+	if err != nil {
+		log.Fatalf("Error parsing --url %s: %v", *urlFlag, err)
+	}
+
+	sc, err := buildScope(startURL, *sameRegisteredDomainFlag, *scopePrefixFlag, scopeAllowFlag, scopeDenyFlag)
+	if err != nil {
+		log.Fatalf("Error building scope: %v", err)
+	}
+
 	log.Printf("Starting sitemap build for %s (depth: %d, workers: %d)\n", *urlFlag, *maxDepth, *workersFlag)
 
-	pages, err := buildSitemap(*urlFlag, *maxDepth, *workersFlag, *statsFlag)
+	pages, err := buildSitemap(*urlFlag, *maxDepth, *workersFlag, *statsFlag, *includeAssetsFlag, *queueDirFlag, *resumeFlag, *userAgentFlag, *minDelayFlag, sc, stripParamsFlag)
 	if err != nil {
 		log.Fatalf("Error building sitemap for %s: %v", *urlFlag, err)
 	}
 
 	log.Printf("Finished crawling. Found %d unique pages.\n", len(pages))
 
+	if *outputDirFlag != "" {
+		if err := writeSitemapFiles(pages, *outputDirFlag, *baseURLFlag, *gzipFlag); err != nil {
+			log.Fatalf("Error writing sitemap files to %s: %v", *outputDirFlag, err)
+		}
+		return
+	}
+
 	xmlBytes, err := generateXMLSitemap(pages)
 	if err != nil {
 		log.Fatalf("Error generating XML sitemap: %v", err)
@@ -77,29 +263,77 @@ func main() {
 }
 
 // buildSitemap crawls the website starting from startURL up to maxDepth
-// and returns a list of unique URLs found within the same domain.
-func buildSitemap(startURL string, maxDepth int, numWorkers int, showStats bool) ([]string, error) {
-	type job struct {
-		url   string
-		depth int
+// and returns a PageInfo per unique URL found within the same domain.
+// Only primary (<a href>) links are followed to expand the crawl
+// frontier; if includeAssets is set, related resources (images, scripts,
+// stylesheets, iframes) are also added to the result without being
+// crawled themselves.
+//
+// Pending jobs and the visited set are tracked through a queue.VisitQueue:
+// an in-memory queue by default, or an on-disk queue.VisitQueue rooted at
+// queueDir when queueDir is non-empty, which can be rehydrated with
+// resume to continue a crawl a previous process left off.
+//
+// Every fetch is routed through a politeness.Politeness, which honors
+// each host's robots.txt and paces requests to it so the crawl doesn't
+// get banned for being impolite.
+//
+// sc decides which discovered links are in scope and, among those,
+// which are primary (followed for further crawling) versus merely
+// included in the output. It replaces the old same-host-only check.
+//
+// Every URL is canonicalized (dropping the query parameters named in
+// stripParams and any trailing slash) before it is visited or added to
+// the output, and fetched pages are additionally deduped by content
+// fingerprint, so mirrored URLs collapse to a single sitemap entry.
+func buildSitemap(startURL string, maxDepth int, numWorkers int, showStats bool, includeAssets bool, queueDir string, resume bool, userAgent string, minDelay time.Duration, sc scope.Scope, stripParams []string) ([]PageInfo, error) {
+	client := newHTTPClient()
+	pol := politeness.New(client, userAgent, minDelay)
+	tracker := dedup.New()
+
+	var vq queue.VisitQueue
+	if queueDir != "" {
+		fq, err := queue.NewFileQueue(queueDir, numWorkers*4, resume)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open on-disk visit queue: %w", err)
+		}
+		vq = fq
+	} else {
+		vq = queue.NewMemQueue(numWorkers * 4)
 	}
 
-	jobs := make(chan job, numWorkers)
+	startURL = dedup.Canonicalize(startURL, stripParams)
+
 	var tasks sync.WaitGroup
-	var visited sync.Map
 	var mu sync.Mutex
-	finalURLs := []string{}
+	pages := make(map[string]*PageInfo)
+
+	// Pages visited by a previous process (when resuming) carry their
+	// real crawl depth from the on-disk visited set, so resuming doesn't
+	// reset their changefreq/priority to a neutral guess.
+	for _, visited := range vq.VisitedURLs() {
+		freq, prio := changeFreqAndPriority(visited.Depth)
+		pages[visited.URL] = &PageInfo{URL: visited.URL, ChangeFreq: freq, Priority: prio}
+	}
 
-	visited.Store(startURL, true)
-	mu.Lock()
-	finalURLs = append(finalURLs, startURL)
-	mu.Unlock()
+	startURLIsNew := !vq.Visit(startURL, 0)
+	if startURLIsNew {
+		freq, prio := changeFreqAndPriority(0)
+		mu.Lock()
+		pages[startURL] = &PageInfo{URL: startURL, ChangeFreq: freq, Priority: prio}
+		mu.Unlock()
+	}
 
 	var scannedCount atomic.Int64
 	var addedCount atomic.Int64
 	var queuedCount atomic.Int64
 	var skippedExtCount atomic.Int64
 
+	if replayed := vq.Replayed(); replayed > 0 {
+		tasks.Add(replayed)
+		queuedCount.Add(int64(replayed))
+	}
+
 	stopStats := make(chan struct{})
 	if showStats {
 		go func() {
@@ -129,74 +363,202 @@ func buildSitemap(startURL string, maxDepth int, numWorkers int, showStats bool)
 		workers.Add(1)
 		go func(workerID int) {
 			defer workers.Done()
-			for j := range jobs {
+			for {
+				j, ok := vq.Dequeue()
+				if !ok {
+					return
+				}
 				scannedCount.Add(1)
 				queuedCount.Add(-1)
 
-				foundLinks, err := getAndParseLinks(j.url)
+				parsedJobURL, err := url.Parse(j.URL)
+				if err != nil {
+					vq.Done(j.URL)
+					tasks.Done()
+					continue
+				}
+				if !pol.Allowed(parsedJobURL) {
+					vq.Done(j.URL)
+					tasks.Done()
+					continue
+				}
+				pol.Wait(parsedJobURL)
+
+				// A job replayed from a --resume queue may not have a
+				// PageInfo yet in this process; back-fill one so the
+				// URL still appears in the output even without it ever
+				// being discovered through the foundLinks loop below.
+				mu.Lock()
+				if _, ok := pages[j.URL]; !ok {
+					freq, prio := changeFreqAndPriority(j.Depth)
+					pages[j.URL] = &PageInfo{URL: j.URL, ChangeFreq: freq, Priority: prio}
+				}
+				mu.Unlock()
+
+				foundLinks, lastMod, body, err := getAndParseLinks(client, userAgent, j.URL)
 				if err != nil {
 					if !strings.Contains(err.Error(), "content type is not HTML") && !strings.Contains(err.Error(),
 						"received non-2xx status code") {
-						log.Printf("Warning (URL: %s): %v", j.url, err)
+						log.Printf("Warning (URL: %s): %v", j.URL, err)
 					}
+					vq.Done(j.URL)
 					tasks.Done()
 					continue
 				}
 
-				if j.depth+1 >= maxDepth {
-					tasks.Done()
-					continue
+				if !lastMod.IsZero() {
+					mu.Lock()
+					if pi, ok := pages[j.URL]; ok {
+						pi.LastMod = lastMod
+					}
+					mu.Unlock()
 				}
 
-				base := getBaseURL(j.url)
+				atMaxDepth := j.Depth+1 >= maxDepth
+
+				base := getBaseURL(j.URL)
 				if base == nil {
+					vq.Done(j.URL)
 					tasks.Done()
 					continue
 				}
 
+				// A declared <link rel="canonical"> takes priority over
+				// this page's own URL: fold its entry under the
+				// canonical URL instead. If the canonical URL already has
+				// its own PageInfo (e.g. it was crawled directly), merge
+				// into that entry rather than replacing it, so a bare
+				// alias pointing at an already-fetched canonical doesn't
+				// clobber real data like its LastMod. A canonical href is
+				// page content, not a link we've vetted, so it only wins
+				// when it passes the same scope/robots checks as any
+				// other discovered URL; otherwise the page keeps its own
+				// key so an out-of-scope canonical can't smuggle a
+				// foreign URL into the sitemap.
+				outputKey := j.URL
+				if canon := canonicalHref(foundLinks, base, stripParams); canon != "" && canon != j.URL && canonAllowed(canon, pol, sc) {
+					mu.Lock()
+					if pi, ok := pages[j.URL]; ok {
+						delete(pages, j.URL)
+						if existing, exists := pages[canon]; exists {
+							if existing.LastMod.IsZero() && !pi.LastMod.IsZero() {
+								existing.LastMod = pi.LastMod
+							}
+						} else {
+							pi.URL = canon
+							pages[canon] = pi
+						}
+					}
+					mu.Unlock()
+					outputKey = canon
+				}
+
+				if _, isDuplicate := tracker.Register(dedup.Fingerprint(body), outputKey); isDuplicate {
+					mu.Lock()
+					delete(pages, outputKey)
+					mu.Unlock()
+				}
+
 				for _, l := range foundLinks {
+					if l.Kind == link.LinkTypeCanonical {
+						continue
+					}
+
 					abs := resolveURL(base, l)
 					if abs == "" {
 						continue
 					}
+					abs = dedup.Canonicalize(abs, stripParams)
 
 					parsedAbs, err := url.Parse(abs)
 					if err != nil {
 						continue
 					}
+					if !pol.Allowed(parsedAbs) {
+						continue
+					}
+
+					include, primary := sc.Check(parsedAbs)
+
+					if l.Kind == link.LinkTypeRelated {
+						if !includeAssets || !include {
+							continue
+						}
+						if !vq.Visit(abs, j.Depth) {
+							freq, prio := changeFreqAndPriority(j.Depth)
+							mu.Lock()
+							pages[abs] = &PageInfo{URL: abs, ChangeFreq: freq, Priority: prio}
+							mu.Unlock()
+							addedCount.Add(1)
+						}
+						continue
+					}
+
+					if include && !primary {
+						// sc says to list abs in the sitemap without
+						// following it to expand the crawl frontier.
+						if !vq.Visit(abs, j.Depth+1) {
+							freq, prio := changeFreqAndPriority(j.Depth + 1)
+							mu.Lock()
+							pages[abs] = &PageInfo{URL: abs, ChangeFreq: freq, Priority: prio}
+							mu.Unlock()
+							addedCount.Add(1)
+						}
+						continue
+					}
+
+					if atMaxDepth {
+						continue
+					}
+
 					ext := strings.ToLower(path.Ext(parsedAbs.Path))
 					if _, ignore := ignoredExtensions[ext]; ignore && ext != "" {
 						skippedExtCount.Add(1)
 						continue
 					}
 
-					if isSameDomain(startURL, abs) {
-						if _, loaded := visited.LoadOrStore(abs, true); !loaded {
+					if include && primary {
+						if !vq.Visit(abs, j.Depth+1) {
+							freq, prio := changeFreqAndPriority(j.Depth + 1)
 							mu.Lock()
-							finalURLs = append(finalURLs, abs)
+							pages[abs] = &PageInfo{URL: abs, ChangeFreq: freq, Priority: prio}
 							mu.Unlock()
 							addedCount.Add(1)
 							tasks.Add(1)
 							queuedCount.Add(1)
-							jobs <- job{url: abs, depth: j.depth + 1}
+							if err := vq.Enqueue(queue.Job{URL: abs, Depth: j.Depth + 1}); err != nil {
+								log.Printf("Warning: failed to enqueue %s: %v", abs, err)
+								tasks.Done()
+							}
 						}
 					}
 				}
 
+				vq.Done(j.URL)
 				tasks.Done()
 			}
 		}(i)
 	}
 
-	tasks.Add(1)
-	queuedCount.Add(1)
-	go func() {
-		jobs <- job{url: startURL, depth: 0}
-	}()
+	// A startURL that Visit already reported as seen was completed by a
+	// previous process (resume); if it was left outstanding instead,
+	// replayPending above already queued it for us. Either way,
+	// enqueuing it again here would re-fetch a page resume is supposed
+	// to skip.
+	if startURLIsNew {
+		tasks.Add(1)
+		queuedCount.Add(1)
+		go func() {
+			if err := vq.Enqueue(queue.Job{URL: startURL, Depth: 0}); err != nil {
+				log.Printf("Warning: failed to enqueue start URL %s: %v", startURL, err)
+				tasks.Done()
+			}
+		}()
+	}
 
 	go func() {
 		tasks.Wait()
-		close(jobs)
+		vq.Close()
 	}()
 
 	workers.Wait()
@@ -206,34 +568,89 @@ func buildSitemap(startURL string, maxDepth int, numWorkers int, showStats bool)
 	}
 	log.Println("\rAll workers finished.")
 
-	return finalURLs, nil
+	finalPages := make([]PageInfo, 0, len(pages))
+	for _, p := range pages {
+		finalPages = append(finalPages, *p)
+	}
+
+	return finalPages, nil
 }
 
-// getAndParseLinks fetches a URL, reads its body, and parses links.
-func getAndParseLinks(urlStr string) ([]link.Link, error) {
-	client := http.Client{
-		Timeout: 15 * time.Second,
+// getAndParseLinks fetches a URL using client, identifying as userAgent,
+// reads its body, and parses links. It also returns the Last-Modified
+// time reported by the response, if any, for use as a sitemap <lastmod>,
+// and the raw response body for content-fingerprint-based dedup.
+func getAndParseLinks(client *http.Client, userAgent string, urlStr string) ([]link.Link, time.Time, []byte, error) {
+	req, err := http.NewRequest(http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, time.Time{}, nil, fmt.Errorf("failed to build request for URL %s: %w", urlStr, err)
 	}
-	resp, err := client.Get(urlStr)
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to GET URL %s: %w", urlStr, err)
+		return nil, time.Time{}, nil, fmt.Errorf("failed to GET URL %s: %w", urlStr, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("received non-2xx status code %d for %s", resp.StatusCode, urlStr)
+		return nil, time.Time{}, nil, fmt.Errorf("received non-2xx status code %d for %s", resp.StatusCode, urlStr)
 	}
 
 	contentType := resp.Header.Get("Content-Type")
 	if !strings.HasPrefix(strings.ToLower(contentType), "text/html") {
-		return nil, fmt.Errorf("content type is not HTML (%s) for %s", contentType, urlStr)
+		return nil, time.Time{}, nil, fmt.Errorf("content type is not HTML (%s) for %s", contentType, urlStr)
 	}
 
-	links, err := link.Parse(resp.Body)
+	var lastMod time.Time
+	if lm := resp.Header.Get("Last-Modified"); lm != "" {
+		if t, err := http.ParseTime(lm); err == nil {
+			lastMod = t
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, time.Time{}, nil, fmt.Errorf("failed to read body for %s: %w", urlStr, err)
+	}
+
+	links, err := link.Parse(bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse links for %s: %w", urlStr, err)
+		return nil, time.Time{}, nil, fmt.Errorf("failed to parse links for %s: %w", urlStr, err)
 	}
-	return links, nil
+	return links, lastMod, body, nil
+}
+
+// canonicalHref resolves the first <link rel="canonical"> reference
+// among links against base and canonicalizes it, or returns "" if there
+// is none.
+func canonicalHref(links []link.Link, base *url.URL, stripParams []string) string {
+	for _, l := range links {
+		if l.Kind != link.LinkTypeCanonical {
+			continue
+		}
+		abs := resolveURL(base, l)
+		if abs == "" {
+			continue
+		}
+		return dedup.Canonicalize(abs, stripParams)
+	}
+	return ""
+}
+
+// canonAllowed reports whether canon may stand in for the page that
+// declared it: it must parse, pass robots.txt for its host, and fall
+// within sc's scope the same as any other discovered URL.
+func canonAllowed(canon string, pol *politeness.Politeness, sc scope.Scope) bool {
+	parsedCanon, err := url.Parse(canon)
+	if err != nil {
+		return false
+	}
+	if !pol.Allowed(parsedCanon) {
+		return false
+	}
+	include, _ := sc.Check(parsedCanon)
+	return include
 }
 
 // getBaseURL parses a URL string and returns its base.
@@ -289,45 +706,170 @@ func resolveURL(base *url.URL, link link.Link) string {
 	return absoluteURL.String()
 }
 
-// isSameDomain checks if a target URL belongs to the same host as the original start URL.
-func isSameDomain(startURLStr, targetURLStr string) bool {
-	start, err := url.Parse(startURLStr)
-	if err != nil {
-		return false
+// generateXMLSitemap creates a single sitemap XML document for pages. It
+// does not enforce the sitemaps.org size limits; use writeSitemapFiles
+// for crawls large enough to need splitting across multiple files.
+func generateXMLSitemap(pages []PageInfo) ([]byte, error) {
+	return buildURLSetXML(dedupePages(pages))
+}
+
+// dedupePages drops pages with an invalid URL and collapses repeats,
+// keeping the first PageInfo seen for each URL.
+func dedupePages(pages []PageInfo) []PageInfo {
+	seen := make(map[string]struct{})
+	deduped := make([]PageInfo, 0, len(pages))
+
+	for _, page := range pages {
+		if _, err := url.ParseRequestURI(page.URL); err != nil {
+			log.Printf("Warning: Skipping invalid URL for sitemap: %s (%v)", page.URL, err)
+			continue
+		}
+
+		if _, exists := seen[page.URL]; exists {
+			continue
+		}
+		seen[page.URL] = struct{}{}
+		deduped = append(deduped, page)
 	}
-	target, err := url.Parse(targetURLStr)
+
+	return deduped
+}
+
+// buildURLSetXML marshals pages into a single <urlset> document.
+func buildURLSetXML(pages []PageInfo) ([]byte, error) {
+	toXML := urlset{Xmlns: xmlns}
+	for _, page := range pages {
+		toXML.Urls = append(toXML.Urls, toURLEntry(page))
+	}
+
+	xmlBytes, err := xml.MarshalIndent(toXML, "", "  ")
 	if err != nil {
-		return false
+		return nil, fmt.Errorf("failed to marshal XML: %w", err)
 	}
 
-	return strings.EqualFold(start.Host, target.Host)
+	finalXML := append([]byte(xml.Header), xmlBytes...)
+
+	return finalXML, nil
 }
 
-// generateXMLSitemap creates the sitemap XML structure.
-func generateXMLSitemap(pages []string) ([]byte, error) {
-	toXML := urlset{
-		Xmlns: xmlns,
+// toURLEntry converts a PageInfo into its <url> XML representation,
+// omitting lastmod/priority when they carry no useful information.
+func toURLEntry(page PageInfo) urlEntry {
+	entry := urlEntry{Loc: page.URL, ChangeFreq: page.ChangeFreq}
+	if !page.LastMod.IsZero() {
+		entry.LastMod = page.LastMod.Format("2006-01-02")
 	}
-	addedUrls := make(map[string]struct{})
+	if page.Priority > 0 {
+		entry.Priority = strconv.FormatFloat(page.Priority, 'f', 1, 64)
+	}
+	return entry
+}
+
+// chunkPages splits pages into groups that each respect the
+// sitemaps.org per-file limits (maxURLsPerSitemap URLs, maxSitemapBytes
+// uncompressed). Sizing is approximate: rather than marshal every
+// candidate chunk to check its exact size, each page's contribution is
+// estimated from its field lengths plus the surrounding XML tags.
+func chunkPages(pages []PageInfo) [][]PageInfo {
+	var chunks [][]PageInfo
+	var current []PageInfo
+	var currentSize int
 
 	for _, page := range pages {
-		if _, err := url.ParseRequestURI(page); err != nil {
-			log.Printf("Warning: Skipping invalid URL for XML sitemap: %s (%v)", page, err)
-			continue
+		size := estimatedEntrySize(page)
+		if len(current) >= maxURLsPerSitemap || (len(current) > 0 && currentSize+size > maxSitemapBytes) {
+			chunks = append(chunks, current)
+			current = nil
+			currentSize = 0
+		}
+		current = append(current, page)
+		currentSize += size
+	}
+
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+
+	return chunks
+}
+
+// estimatedEntrySize is a rough upper bound on the marshaled size of a
+// single <url> element, used by chunkPages to stay under the
+// sitemaps.org 50 MiB per-file guidance. The fixed part covers the
+// indented tags plus a full lastmod and priority (126 bytes); growth of
+// the URL from XML escaping (e.g. & to &amp;) is not counted.
+func estimatedEntrySize(page PageInfo) int {
+	return len(page.URL) + len(page.ChangeFreq) + 128
+}
+
+// writeSitemapFiles splits pages across one or more sitemap-N.xml(.gz)
+// files under outputDir, plus a sitemap_index.xml that references each
+// by its absolute URL (outputDir's files served relative to
+// baseURL), per the sitemaps.org 50,000-URL / 50 MiB per-file limits.
+func writeSitemapFiles(pages []PageInfo, outputDir, baseURL string, useGzip bool) error {
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output dir %s: %w", outputDir, err)
+	}
+
+	chunks := chunkPages(dedupePages(pages))
+	if len(chunks) == 0 {
+		chunks = [][]PageInfo{{}}
+	}
+
+	indexEntries := make([]sitemapIndexEntry, 0, len(chunks))
+	for i, chunk := range chunks {
+		xmlBytes, err := buildURLSetXML(chunk)
+		if err != nil {
+			return fmt.Errorf("failed to marshal sitemap %d: %w", i+1, err)
+		}
+
+		name := fmt.Sprintf("sitemap-%d.xml", i+1)
+		if useGzip {
+			name += ".gz"
 		}
 
-		if _, exists := addedUrls[page]; !exists {
-			toXML.Urls = append(toXML.Urls, loc{page})
-			addedUrls[page] = struct{}{}
+		if err := writeSitemapFile(filepath.Join(outputDir, name), xmlBytes, useGzip); err != nil {
+			return err
 		}
+
+		indexEntries = append(indexEntries, sitemapIndexEntry{Loc: strings.TrimSuffix(baseURL, "/") + "/" + name})
 	}
 
+	return writeSitemapIndex(filepath.Join(outputDir, "sitemap_index.xml"), indexEntries)
+}
+
+// writeSitemapFile writes xmlBytes to path, gzipping it first when
+// useGzip is set.
+func writeSitemapFile(path string, xmlBytes []byte, useGzip bool) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if !useGzip {
+		_, err = f.Write(xmlBytes)
+		return err
+	}
+
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write(xmlBytes); err != nil {
+		gz.Close()
+		return fmt.Errorf("failed to gzip %s: %w", path, err)
+	}
+	return gz.Close()
+}
+
+// writeSitemapIndex writes a sitemap_index.xml referencing entries.
+func writeSitemapIndex(path string, entries []sitemapIndexEntry) error {
+	toXML := sitemapIndex{Sitemaps: entries, Xmlns: xmlns}
+
 	xmlBytes, err := xml.MarshalIndent(toXML, "", "  ")
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal XML: %w", err)
+		return fmt.Errorf("failed to marshal sitemap index: %w", err)
 	}
 
 	finalXML := append([]byte(xml.Header), xmlBytes...)
 
-	return finalXML, nil
+	return os.WriteFile(path, finalXML, 0o644)
 }
@@ -0,0 +1,110 @@
+// Package politeness keeps the crawler from hammering or getting banned
+// by the sites it visits: it honors robots.txt and paces requests to
+// each host independently so overall crawl concurrency stays high while
+// no single origin sees more than one request per its crawl-delay.
+package politeness
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Politeness answers whether a URL may be fetched and paces requests per
+// host, based on each host's robots.txt (fetched and cached lazily) and
+// a configured User-Agent.
+type Politeness struct {
+	client    *http.Client
+	userAgent string
+	minDelay  time.Duration
+
+	limiter *hostLimiter
+
+	mu     sync.Mutex
+	robots map[string]*hostRobots
+}
+
+// hostRobots lazily fetches and caches the robots.txt rules for a single
+// host. The fetch runs at most once per host, guarded by once rather
+// than by Politeness's map mutex, so a slow fetch for one host doesn't
+// block Allowed/Wait calls for every other host.
+type hostRobots struct {
+	once  sync.Once
+	rules *robotsRules
+}
+
+// New returns a Politeness that fetches robots.txt using client,
+// identifies itself as userAgent, and paces requests to a host at no
+// less than minDelay apart unless that host's robots.txt specifies a
+// longer Crawl-delay.
+func New(client *http.Client, userAgent string, minDelay time.Duration) *Politeness {
+	return &Politeness{
+		client:    client,
+		userAgent: userAgent,
+		minDelay:  minDelay,
+		limiter:   newHostLimiter(),
+		robots:    make(map[string]*hostRobots),
+	}
+}
+
+// Allowed reports whether u may be fetched under its host's robots.txt
+// rules for p's User-Agent.
+func (p *Politeness) Allowed(u *url.URL) bool {
+	return p.rulesFor(u).allowed(u.Path)
+}
+
+// Wait blocks until it is polite to issue the next request to u's host,
+// honoring that host's robots.txt Crawl-delay or falling back to
+// minDelay.
+func (p *Politeness) Wait(u *url.URL) {
+	delay := p.minDelay
+	if rules := p.rulesFor(u); rules.crawlDelay > delay {
+		delay = rules.crawlDelay
+	}
+	p.limiter.Wait(u.Host, delay)
+}
+
+// rulesFor returns the cached robots.txt rules for u's host, fetching
+// and parsing them on first use. The map lookup/insert is the only part
+// done under p.mu; the fetch itself runs under the host's own once, so
+// concurrent requests to other hosts are never blocked behind it.
+func (p *Politeness) rulesFor(u *url.URL) *robotsRules {
+	p.mu.Lock()
+	hr, ok := p.robots[u.Host]
+	if !ok {
+		hr = &hostRobots{}
+		p.robots[u.Host] = hr
+	}
+	p.mu.Unlock()
+
+	hr.once.Do(func() {
+		hr.rules = p.fetchRobots(u)
+	})
+	return hr.rules
+}
+
+// fetchRobots retrieves and parses the robots.txt for u's host. Any
+// failure to fetch or parse it is treated as "no restrictions", which is
+// the conventional behavior when a site has no robots.txt.
+func (p *Politeness) fetchRobots(u *url.URL) *robotsRules {
+	robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+
+	req, err := http.NewRequest(http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return &robotsRules{}
+	}
+	req.Header.Set("User-Agent", p.userAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return &robotsRules{}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &robotsRules{}
+	}
+
+	return parseRobots(resp.Body, p.userAgent)
+}
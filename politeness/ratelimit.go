@@ -0,0 +1,40 @@
+package politeness
+
+import (
+	"sync"
+	"time"
+)
+
+// hostLimiter enforces a minimum delay between requests to each host,
+// independently per host, so a slow or strict origin doesn't throttle
+// the rest of the crawl.
+type hostLimiter struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func newHostLimiter() *hostLimiter {
+	return &hostLimiter{next: make(map[string]time.Time)}
+}
+
+// Wait blocks the calling goroutine until it may issue the next request
+// to host, given delay as the minimum spacing between requests to that
+// host. A non-positive delay is a no-op.
+func (h *hostLimiter) Wait(host string, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+
+	h.mu.Lock()
+	now := time.Now()
+	wait := time.Duration(0)
+	if next, ok := h.next[host]; ok && next.After(now) {
+		wait = next.Sub(now)
+	}
+	h.next[host] = now.Add(wait + delay)
+	h.mu.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
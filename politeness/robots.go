@@ -0,0 +1,110 @@
+package politeness
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// robotsRules holds the Disallow prefixes and Crawl-delay that apply to
+// a single User-Agent group in a robots.txt file.
+type robotsRules struct {
+	disallow   []string
+	crawlDelay time.Duration
+}
+
+// allowed reports whether path is permitted by these rules.
+func (r *robotsRules) allowed(path string) bool {
+	for _, prefix := range r.disallow {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// robotsGroup is a single "User-agent: ..." block before parsing picks
+// the one that applies to our User-Agent.
+type robotsGroup struct {
+	agents   []string
+	disallow []string
+	delay    time.Duration
+}
+
+// parseRobots reads a robots.txt body and returns the rules for the
+// group that most specifically matches userAgent, falling back to the
+// wildcard "*" group, or an empty (unrestricted) ruleset if neither a
+// matching nor a wildcard group exists.
+func parseRobots(body io.Reader, userAgent string) *robotsRules {
+	var groups []*robotsGroup
+	var current *robotsGroup
+	sawDirective := false
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.IndexByte(line, '#'); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = strings.TrimSpace(val)
+
+		switch key {
+		case "user-agent":
+			if current == nil || sawDirective {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+				sawDirective = false
+			}
+			current.agents = append(current.agents, strings.ToLower(val))
+		case "disallow":
+			if current != nil {
+				sawDirective = true
+				if val != "" {
+					current.disallow = append(current.disallow, val)
+				}
+			}
+		case "crawl-delay":
+			if current != nil {
+				sawDirective = true
+				if secs, err := strconv.ParseFloat(val, 64); err == nil {
+					current.delay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	ua := strings.ToLower(userAgent)
+	var wildcard, matched *robotsGroup
+	matchedLen := 0
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			if agent == "*" {
+				wildcard = g
+			} else if ua != "" && strings.Contains(ua, agent) && len(agent) > matchedLen {
+				matched = g
+				matchedLen = len(agent)
+			}
+		}
+	}
+
+	best := matched
+	if best == nil {
+		best = wildcard
+	}
+	if best == nil {
+		return &robotsRules{}
+	}
+	return &robotsRules{disallow: best.disallow, crawlDelay: best.delay}
+}
@@ -0,0 +1,81 @@
+package politeness
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRobotsWildcardGroup(t *testing.T) {
+	body := `
+User-agent: *
+Disallow: /private/
+Crawl-delay: 2
+`
+	rules := parseRobots(strings.NewReader(body), "sitemap-crawler")
+
+	if rules.allowed("/private/page") {
+		t.Errorf("expected /private/page to be disallowed")
+	}
+	if !rules.allowed("/public/page") {
+		t.Errorf("expected /public/page to be allowed")
+	}
+	if rules.crawlDelay != 2*time.Second {
+		t.Errorf("expected crawl-delay of 2s, got %v", rules.crawlDelay)
+	}
+}
+
+func TestParseRobotsPrefersSpecificGroup(t *testing.T) {
+	body := `
+User-agent: *
+Disallow: /everyone/
+
+User-agent: sitemap-crawler
+Disallow: /just-us/
+Crawl-delay: 1
+`
+	rules := parseRobots(strings.NewReader(body), "sitemap-crawler/1.0")
+
+	if !rules.allowed("/everyone/page") {
+		t.Errorf("expected the specific group to replace the wildcard group, not merge with it")
+	}
+	if rules.allowed("/just-us/page") {
+		t.Errorf("expected /just-us/page to be disallowed")
+	}
+}
+
+func TestParseRobotsNoMatchingGroup(t *testing.T) {
+	body := `
+User-agent: some-other-bot
+Disallow: /
+`
+	rules := parseRobots(strings.NewReader(body), "sitemap-crawler")
+
+	if !rules.allowed("/anything") {
+		t.Errorf("expected no restrictions when no group matches and there is no wildcard group")
+	}
+}
+
+func TestHostLimiterPacesPerHost(t *testing.T) {
+	hl := newHostLimiter()
+
+	start := time.Now()
+	hl.Wait("a.example.com", 50*time.Millisecond)
+	hl.Wait("a.example.com", 50*time.Millisecond)
+	elapsed := time.Since(start)
+	if elapsed < 50*time.Millisecond {
+		t.Errorf("expected second request to the same host to wait, elapsed %v", elapsed)
+	}
+}
+
+func TestHostLimiterDoesNotCrossHosts(t *testing.T) {
+	hl := newHostLimiter()
+
+	start := time.Now()
+	hl.Wait("a.example.com", time.Second)
+	hl.Wait("b.example.com", time.Second)
+	elapsed := time.Since(start)
+	if elapsed >= time.Second {
+		t.Errorf("expected a different host not to be throttled by another host's delay, elapsed %v", elapsed)
+	}
+}
@@ -0,0 +1,89 @@
+package queue
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// bloomFilter is a fixed-size probabilistic set used as the visited-URL
+// membership check for FileQueue: Test may occasionally report a URL as
+// present when it was never added (a false positive), but never the
+// reverse. In exchange, its memory footprint is fixed at construction
+// time instead of growing with every URL a crawl discovers, which is
+// what actually bounds RAM on multi-million-URL crawls. Callers must
+// synchronize access; bloomFilter has no internal locking.
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+	k    int
+}
+
+// newBloomFilter sizes a filter so that, once expectedItems have been
+// added, a Test on a URL that was never added has roughly
+// falsePositiveRate odds of (incorrectly) returning true.
+func newBloomFilter(expectedItems uint64, falsePositiveRate float64) *bloomFilter {
+	if expectedItems == 0 {
+		expectedItems = 1
+	}
+	m := bloomBits(expectedItems, falsePositiveRate)
+	return &bloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    bloomHashCount(m, expectedItems),
+	}
+}
+
+// bloomBits computes the optimal bit-array size for n items at false
+// positive rate p.
+func bloomBits(n uint64, p float64) uint64 {
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	if m < 64 {
+		m = 64
+	}
+	return uint64(m)
+}
+
+// bloomHashCount computes the optimal number of hash functions for an
+// m-bit filter holding n items.
+func bloomHashCount(m, n uint64) int {
+	k := int(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return k
+}
+
+// indexes derives b.k bit positions for s using double hashing
+// (Kirsch-Mitzenmacher), which needs only two underlying hashes instead
+// of k independent ones.
+func (b *bloomFilter) indexes(s string, visit func(idx uint64)) {
+	h1 := fnv.New64a()
+	h1.Write([]byte(s))
+	sum1 := h1.Sum64()
+
+	h2 := fnv.New64()
+	h2.Write([]byte(s))
+	sum2 := h2.Sum64()
+
+	for i := 0; i < b.k; i++ {
+		visit((sum1 + uint64(i)*sum2) % b.m)
+	}
+}
+
+// Add records s as a member of the set.
+func (b *bloomFilter) Add(s string) {
+	b.indexes(s, func(idx uint64) {
+		b.bits[idx/64] |= 1 << (idx % 64)
+	})
+}
+
+// Test reports whether s was (probably) added before.
+func (b *bloomFilter) Test(s string) bool {
+	present := true
+	b.indexes(s, func(idx uint64) {
+		if b.bits[idx/64]&(1<<(idx%64)) == 0 {
+			present = false
+		}
+	})
+	return present
+}
@@ -0,0 +1,405 @@
+package queue
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	pendingLogName = "pending.jsonl"
+	visitedSetName = "visited.jsonl"
+
+	// fsyncBatch controls how often appends to the on-disk log files are
+	// flushed, trading some durability for write throughput.
+	fsyncBatch = 100
+
+	// bloomExpectedItems and bloomFalsePositiveRate size the in-memory
+	// visited-membership filter. At this capacity and false-positive
+	// rate the bit array is about 6 MB (~48M bits), a fixed cost
+	// regardless of how many URLs a crawl actually discovers - unlike a
+	// plain map, which would grow without bound. Crawls well beyond this
+	// capacity still work, just with a gradually rising (and always
+	// one-sided: never-visited URLs, not duplicates) false-positive rate.
+	bloomExpectedItems     = 5_000_000
+	bloomFalsePositiveRate = 0.01
+)
+
+// pendingRecord is the on-disk representation of a pending-log entry. A
+// record with Done set is a tombstone: it marks that the job for URL has
+// finished processing (successfully or not), so it must not be replayed
+// even though its original Enqueue record may still be in the log.
+type pendingRecord struct {
+	Job
+	Done bool `json:"Done,omitempty"`
+}
+
+// FileQueue is a VisitQueue backed by an append-only pending-job log and
+// an on-disk visited-URL set, so RAM use stays flat no matter how many
+// URLs a crawl discovers. A crawl can be resumed from the same directory
+// after a restart via NewFileQueue's resume argument.
+//
+// Pending jobs are delivered to workers over a bounded channel; a job is
+// only skipped on resume once Done has tombstoned it, so a crash mid-job
+// replays at most the handful of jobs that were in flight when the
+// process stopped. Fetching is idempotent, so this is harmless.
+type FileQueue struct {
+	jobs chan Job
+
+	mu            sync.Mutex
+	visited       *bloomFilter
+	visitedFile   *os.File
+	visitedWrites int
+
+	pendingFile   *os.File
+	pendingWrites int
+
+	replayedCount int
+
+	drain     sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewFileQueue opens the on-disk queue rooted at dir, creating it if
+// necessary. When resume is false, any existing state in dir is wiped so
+// the crawl starts clean; when resume is true, the visited set and any
+// still-outstanding jobs left over from a previous run are rehydrated
+// before the queue is returned.
+func NewFileQueue(dir string, bufferSize int, resume bool) (*FileQueue, error) {
+	if !resume {
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, fmt.Errorf("failed to clear queue dir %s: %w", dir, err)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create queue dir %s: %w", dir, err)
+	}
+
+	q := &FileQueue{
+		jobs:    make(chan Job, bufferSize),
+		visited: newBloomFilter(bloomExpectedItems, bloomFalsePositiveRate),
+	}
+
+	visitedPath := filepath.Join(dir, visitedSetName)
+	if resume {
+		if err := q.loadVisited(visitedPath); err != nil {
+			return nil, err
+		}
+	}
+	visitedFile, err := os.OpenFile(visitedPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open visited set %s: %w", visitedPath, err)
+	}
+	q.visitedFile = visitedFile
+
+	pendingPath := filepath.Join(dir, pendingLogName)
+	pendingFile, err := os.OpenFile(pendingPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pending log %s: %w", pendingPath, err)
+	}
+	q.pendingFile = pendingFile
+
+	if resume {
+		if err := q.replayPending(pendingPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return q, nil
+}
+
+func (q *FileQueue) loadVisited(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read visited set %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var j Job
+		if err := json.Unmarshal(line, &j); err != nil {
+			continue
+		}
+		q.visited.Add(j.URL)
+	}
+	return scanner.Err()
+}
+
+// replayPending streams the jobs left outstanding in the pending log
+// back onto the job channel from a background goroutine, so NewFileQueue
+// can return without blocking on a channel that isn't being drained yet.
+// A job is outstanding if it was enqueued but never tombstoned by a
+// matching Done call.
+func (q *FileQueue) replayPending(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read pending log %s: %w", path, err)
+	}
+	defer f.Close()
+
+	outstanding := make(map[string]Job)
+	var order []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec pendingRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		if rec.Done {
+			delete(outstanding, rec.URL)
+			continue
+		}
+		if _, seen := outstanding[rec.URL]; !seen {
+			order = append(order, rec.URL)
+		}
+		outstanding[rec.URL] = rec.Job
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read pending log %s: %w", path, err)
+	}
+
+	replayed := make([]Job, 0, len(outstanding))
+	for _, url := range order {
+		if j, ok := outstanding[url]; ok {
+			replayed = append(replayed, j)
+		}
+	}
+
+	q.replayedCount = len(replayed)
+
+	q.drain.Add(1)
+	go func() {
+		defer q.drain.Done()
+		for _, j := range replayed {
+			q.jobs <- j
+		}
+	}()
+	return nil
+}
+
+func (q *FileQueue) Enqueue(j Job) error {
+	data, err := json.Marshal(pendingRecord{Job: j})
+	if err != nil {
+		return fmt.Errorf("failed to marshal job %+v: %w", j, err)
+	}
+
+	q.mu.Lock()
+	_, err = q.pendingFile.Write(append(data, '\n'))
+	if err == nil {
+		q.pendingWrites++
+		if q.pendingWrites%fsyncBatch == 0 {
+			err = q.pendingFile.Sync()
+		}
+	}
+	q.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to append pending job: %w", err)
+	}
+
+	q.jobs <- j
+	return nil
+}
+
+func (q *FileQueue) Dequeue() (Job, bool) {
+	j, ok := <-q.jobs
+	return j, ok
+}
+
+// Done tombstones url in the pending log so a later replay (whether from
+// a clean Close's compaction or from reading an uncompacted log after a
+// crash) skips it instead of re-fetching it.
+func (q *FileQueue) Done(url string) error {
+	data, err := json.Marshal(pendingRecord{Job: Job{URL: url}, Done: true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal done marker for %s: %w", url, err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, err := q.pendingFile.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to append done marker for %s: %w", url, err)
+	}
+	q.pendingWrites++
+	if q.pendingWrites%fsyncBatch == 0 {
+		return q.pendingFile.Sync()
+	}
+	return nil
+}
+
+func (q *FileQueue) Visit(url string, depth int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.visited.Test(url) {
+		return true
+	}
+	q.visited.Add(url)
+
+	if data, err := json.Marshal(Job{URL: url, Depth: depth}); err == nil {
+		if _, err := q.visitedFile.Write(append(data, '\n')); err == nil {
+			q.visitedWrites++
+			if q.visitedWrites%fsyncBatch == 0 {
+				q.visitedFile.Sync()
+			}
+		}
+	}
+	return false
+}
+
+// Replayed returns the number of pending jobs rehydrated from a previous
+// run. Callers resuming a crawl must account for these (e.g. against a
+// WaitGroup) before draining the queue, since they were enqueued by a
+// process that is no longer around to do so.
+func (q *FileQueue) Replayed() int {
+	return q.replayedCount
+}
+
+// VisitedURLs reads the on-disk visited set back from disk on each call
+// rather than keeping a copy buffered in memory, so FileQueue's own
+// steady-state visited-set memory is just the fixed-size bloom filter.
+// The returned slice holds every visited job.
+func (q *FileQueue) VisitedURLs() []Job {
+	q.mu.Lock()
+	q.visitedFile.Sync()
+	path := q.visitedFile.Name()
+	q.mu.Unlock()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var jobs []Job
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var j Job
+		if err := json.Unmarshal(line, &j); err != nil {
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs
+}
+
+// Close waits for any replayed jobs to finish draining, closes the job
+// channel, flushes the on-disk logs, and compacts the pending log down
+// to just its outstanding (non-tombstoned) jobs, so a later --resume
+// only replays work that never finished.
+func (q *FileQueue) Close() error {
+	var err error
+	q.closeOnce.Do(func() {
+		q.drain.Wait()
+		close(q.jobs)
+
+		q.mu.Lock()
+		pendingPath := q.pendingFile.Name()
+		if syncErr := q.pendingFile.Sync(); syncErr != nil {
+			err = syncErr
+		}
+		if syncErr := q.visitedFile.Sync(); syncErr != nil && err == nil {
+			err = syncErr
+		}
+		if closeErr := q.pendingFile.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if closeErr := q.visitedFile.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		q.mu.Unlock()
+
+		if err == nil {
+			err = compactPendingLog(pendingPath)
+		}
+	})
+	return err
+}
+
+// compactPendingLog rewrites the pending log at path to contain only
+// jobs that were enqueued but never tombstoned by Done.
+func compactPendingLog(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read pending log %s: %w", path, err)
+	}
+
+	outstanding := make(map[string]Job)
+	var order []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec pendingRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		if rec.Done {
+			delete(outstanding, rec.URL)
+			continue
+		}
+		if _, seen := outstanding[rec.URL]; !seen {
+			order = append(order, rec.URL)
+		}
+		outstanding[rec.URL] = rec.Job
+	}
+	closeErr := f.Close()
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read pending log %s: %w", path, err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to read pending log %s: %w", path, closeErr)
+	}
+
+	tmpPath := path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create compacted pending log %s: %w", tmpPath, err)
+	}
+	w := bufio.NewWriter(tmp)
+	for _, url := range order {
+		j, ok := outstanding[url]
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(pendingRecord{Job: j})
+		if err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to marshal job %+v: %w", j, err)
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write compacted pending log %s: %w", tmpPath, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to flush compacted pending log %s: %w", tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close compacted pending log %s: %w", tmpPath, err)
+	}
+	return os.Rename(tmpPath, path)
+}
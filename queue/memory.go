@@ -0,0 +1,67 @@
+package queue
+
+import "sync"
+
+// MemQueue is the default VisitQueue: jobs are buffered in a channel and
+// the visited set lives entirely in memory. It has no durability - a
+// crashed or killed process loses all crawl progress.
+type MemQueue struct {
+	jobs chan Job
+
+	mu      sync.Mutex
+	visited map[string]struct{}
+	order   []Job
+}
+
+// NewMemQueue returns a MemQueue whose job channel is buffered to
+// bufferSize.
+func NewMemQueue(bufferSize int) *MemQueue {
+	return &MemQueue{
+		jobs:    make(chan Job, bufferSize),
+		visited: make(map[string]struct{}),
+	}
+}
+
+func (q *MemQueue) Enqueue(j Job) error {
+	q.jobs <- j
+	return nil
+}
+
+func (q *MemQueue) Dequeue() (Job, bool) {
+	j, ok := <-q.jobs
+	return j, ok
+}
+
+func (q *MemQueue) Visit(url string, depth int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, seen := q.visited[url]; seen {
+		return true
+	}
+	q.visited[url] = struct{}{}
+	q.order = append(q.order, Job{URL: url, Depth: depth})
+	return false
+}
+
+func (q *MemQueue) VisitedURLs() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]Job, len(q.order))
+	copy(jobs, q.order)
+	return jobs
+}
+
+func (q *MemQueue) Replayed() int {
+	return 0
+}
+
+// Done is a no-op: MemQueue has no durable state for a future process to
+// resume from.
+func (q *MemQueue) Done(url string) error {
+	return nil
+}
+
+func (q *MemQueue) Close() error {
+	close(q.jobs)
+	return nil
+}
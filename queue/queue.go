@@ -0,0 +1,47 @@
+// Package queue provides the pending-job/visited-URL bookkeeping used by
+// the crawler. Two implementations are provided: an in-memory queue for
+// ordinary crawls, and a file-backed queue that bounds memory use and can
+// resume a crawl left off by a previous process.
+package queue
+
+// Job is a single unit of crawl work: a URL to fetch at a given depth.
+type Job struct {
+	URL   string
+	Depth int
+}
+
+// VisitQueue tracks pending crawl jobs and which URLs have already been
+// seen, so the crawler doesn't enqueue or report the same URL twice.
+type VisitQueue interface {
+	// Enqueue adds a job for a worker to pick up. It may block if the
+	// underlying queue applies backpressure.
+	Enqueue(j Job) error
+
+	// Dequeue blocks until a job is available, returning ok=false once
+	// the queue has been closed and fully drained.
+	Dequeue() (j Job, ok bool)
+
+	// Visit records that url, found at depth, has been seen, returning
+	// true if it was already visited. Callers should only enqueue/report
+	// a URL when Visit returns false.
+	Visit(url string, depth int) (alreadyVisited bool)
+
+	// VisitedURLs returns every job recorded via Visit so far, each with
+	// the depth it was first visited at.
+	VisitedURLs() []Job
+
+	// Replayed returns the number of pending jobs that were rehydrated
+	// from a previous run and will be delivered by Dequeue without a
+	// matching Enqueue call in this process.
+	Replayed() int
+
+	// Done marks the job for url as fully processed, so a future
+	// --resume won't replay it even if its Enqueue record is still in
+	// the on-disk log. Implementations without durable state may treat
+	// this as a no-op.
+	Done(url string) error
+
+	// Close signals that no more jobs will be enqueued. Pending Dequeue
+	// calls drain any buffered jobs and then return ok=false.
+	Close() error
+}
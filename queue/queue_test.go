@@ -0,0 +1,141 @@
+package queue_test
+
+import (
+	"os"
+	"testing"
+
+	"sitemap/queue"
+)
+
+func TestMemQueueVisitDedup(t *testing.T) {
+	q := queue.NewMemQueue(4)
+
+	if q.Visit("https://example.com/a", 0) {
+		t.Fatalf("expected first visit to be new")
+	}
+	if !q.Visit("https://example.com/a", 0) {
+		t.Fatalf("expected second visit to report already visited")
+	}
+
+	if err := q.Enqueue(queue.Job{URL: "https://example.com/a", Depth: 0}); err != nil {
+		t.Fatalf("unexpected enqueue error: %v", err)
+	}
+	q.Close()
+
+	j, ok := q.Dequeue()
+	if !ok || j.URL != "https://example.com/a" {
+		t.Fatalf("expected to dequeue enqueued job, got %+v, ok=%v", j, ok)
+	}
+
+	if _, ok := q.Dequeue(); ok {
+		t.Fatalf("expected dequeue to report closed queue after drain")
+	}
+}
+
+func TestFileQueueResume(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := queue.NewFileQueue(dir, 4, false)
+	if err != nil {
+		t.Fatalf("NewFileQueue failed: %v", err)
+	}
+
+	q.Visit("https://example.com/start", 0)
+	if err := q.Enqueue(queue.Job{URL: "https://example.com/next", Depth: 1}); err != nil {
+		t.Fatalf("unexpected enqueue error: %v", err)
+	}
+	if _, ok := q.Dequeue(); !ok {
+		t.Fatalf("expected to dequeue job before close")
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	resumed, err := queue.NewFileQueue(dir, 4, true)
+	if err != nil {
+		t.Fatalf("NewFileQueue (resume) failed: %v", err)
+	}
+	defer resumed.Close()
+
+	urls := resumed.VisitedURLs()
+	if len(urls) != 1 || urls[0].URL != "https://example.com/start" || urls[0].Depth != 0 {
+		t.Fatalf("expected resumed visited set to contain start URL at depth 0, got %v", urls)
+	}
+
+	if !resumed.Visit("https://example.com/start", 0) {
+		t.Fatalf("expected resumed queue to recall start URL as visited")
+	}
+
+	// "next" was dequeued but never marked Done, so it's still
+	// outstanding and must be replayed.
+	j, ok := resumed.Dequeue()
+	if !ok || j.URL != "https://example.com/next" {
+		t.Fatalf("expected replayed pending job, got %+v, ok=%v", j, ok)
+	}
+}
+
+func TestFileQueueResumeSkipsCompletedJobs(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := queue.NewFileQueue(dir, 4, false)
+	if err != nil {
+		t.Fatalf("NewFileQueue failed: %v", err)
+	}
+
+	q.Visit("https://example.com/start", 0)
+	if err := q.Enqueue(queue.Job{URL: "https://example.com/next", Depth: 1}); err != nil {
+		t.Fatalf("unexpected enqueue error: %v", err)
+	}
+	if _, ok := q.Dequeue(); !ok {
+		t.Fatalf("expected to dequeue job before close")
+	}
+	if err := q.Done("https://example.com/next"); err != nil {
+		t.Fatalf("unexpected done error: %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	resumed, err := queue.NewFileQueue(dir, 4, true)
+	if err != nil {
+		t.Fatalf("NewFileQueue (resume) failed: %v", err)
+	}
+	defer resumed.Close()
+
+	if replayed := resumed.Replayed(); replayed != 0 {
+		t.Fatalf("expected no jobs replayed once completed, got %d", replayed)
+	}
+
+	if err := resumed.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+	if _, ok := resumed.Dequeue(); ok {
+		t.Fatalf("expected no pending job to replay after resume")
+	}
+}
+
+func TestFileQueueNoResumeClearsState(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := queue.NewFileQueue(dir, 4, false)
+	if err != nil {
+		t.Fatalf("NewFileQueue failed: %v", err)
+	}
+	q.Visit("https://example.com/stale", 0)
+	if err := q.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	fresh, err := queue.NewFileQueue(dir, 4, false)
+	if err != nil {
+		t.Fatalf("NewFileQueue failed: %v", err)
+	}
+	defer fresh.Close()
+
+	if urls := fresh.VisitedURLs(); len(urls) != 0 {
+		t.Fatalf("expected fresh queue to have no visited URLs, got %v", urls)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("expected queue dir to exist: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package scope
+
+import "net/url"
+
+// All combines scopes with AND semantics: a URL is included only if
+// every child scope includes it, and primary only if every child also
+// marks it primary.
+type All []Scope
+
+func (s All) Check(u *url.URL) (bool, bool) {
+	primary := true
+	for _, child := range s {
+		include, childPrimary := child.Check(u)
+		if !include {
+			return false, false
+		}
+		if !childPrimary {
+			primary = false
+		}
+	}
+	return true, primary
+}
+
+// Any combines scopes with OR semantics: a URL is included if any child
+// scope includes it, taking that child's primary verdict.
+type Any []Scope
+
+func (s Any) Check(u *url.URL) (bool, bool) {
+	for _, child := range s {
+		if include, primary := child.Check(u); include {
+			return true, primary
+		}
+	}
+	return false, false
+}
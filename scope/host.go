@@ -0,0 +1,22 @@
+package scope
+
+import (
+	"net/url"
+	"strings"
+)
+
+// SameHost includes URLs whose host exactly matches Host
+// (case-insensitively) - the crawler's original behavior.
+type SameHost struct {
+	Host string
+}
+
+// NewSameHost builds a SameHost scope for startURL's host.
+func NewSameHost(startURL *url.URL) *SameHost {
+	return &SameHost{Host: startURL.Host}
+}
+
+func (s *SameHost) Check(u *url.URL) (bool, bool) {
+	ok := strings.EqualFold(s.Host, u.Host)
+	return ok, ok
+}
@@ -0,0 +1,17 @@
+package scope
+
+import (
+	"net/url"
+	"strings"
+)
+
+// PathPrefix includes URLs whose path starts with Prefix, scoping a
+// crawl to a subtree such as /docs/.
+type PathPrefix struct {
+	Prefix string
+}
+
+func (s *PathPrefix) Check(u *url.URL) (bool, bool) {
+	ok := strings.HasPrefix(u.Path, s.Prefix)
+	return ok, ok
+}
@@ -0,0 +1,41 @@
+package scope
+
+import (
+	"net/url"
+	"regexp"
+)
+
+// RegexAllow includes a URL if its string form matches any of Patterns.
+// An empty Patterns matches every URL, so it can be omitted when only a
+// deny list is needed.
+type RegexAllow struct {
+	Patterns []*regexp.Regexp
+}
+
+func (s *RegexAllow) Check(u *url.URL) (bool, bool) {
+	if len(s.Patterns) == 0 {
+		return true, true
+	}
+	str := u.String()
+	for _, p := range s.Patterns {
+		if p.MatchString(str) {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// RegexDeny excludes a URL if its string form matches any of Patterns.
+type RegexDeny struct {
+	Patterns []*regexp.Regexp
+}
+
+func (s *RegexDeny) Check(u *url.URL) (bool, bool) {
+	str := u.String()
+	for _, p := range s.Patterns {
+		if p.MatchString(str) {
+			return false, false
+		}
+	}
+	return true, true
+}
@@ -0,0 +1,39 @@
+package scope
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// SameRegisteredDomain includes any URL whose host shares the same
+// registered domain (eTLD+1) as the start URL, so www.example.com and
+// blog.example.com are treated as one scope.
+type SameRegisteredDomain struct {
+	domain string
+}
+
+// NewSameRegisteredDomain builds a SameRegisteredDomain scope for
+// startURL's registered domain.
+func NewSameRegisteredDomain(startURL *url.URL) (*SameRegisteredDomain, error) {
+	domain, err := registeredDomain(startURL.Hostname())
+	if err != nil {
+		return nil, fmt.Errorf("could not determine registered domain for %s: %w", startURL, err)
+	}
+	return &SameRegisteredDomain{domain: domain}, nil
+}
+
+func (s *SameRegisteredDomain) Check(u *url.URL) (bool, bool) {
+	domain, err := registeredDomain(u.Hostname())
+	if err != nil {
+		return false, false
+	}
+	ok := domain == s.domain
+	return ok, ok
+}
+
+func registeredDomain(host string) (string, error) {
+	return publicsuffix.EffectiveTLDPlusOne(strings.ToLower(host))
+}
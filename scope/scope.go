@@ -0,0 +1,17 @@
+// Package scope decides which discovered URLs a crawl should treat as
+// in-bounds, replacing a single hardcoded same-host check with rules
+// that can be composed: same-host, same-registered-domain, path-prefix,
+// and regex allow/deny lists.
+package scope
+
+import "net/url"
+
+// Scope reports whether a URL falls within a crawl's boundaries. Check
+// returns include, whether u should appear in the sitemap at all, and
+// primary, whether u should also be followed to expand the crawl
+// frontier. Every Scope in this package treats the two identically; the
+// split exists so a composed Scope can tell a caller "list this URL but
+// don't crawl from it" once a rule needs that distinction.
+type Scope interface {
+	Check(u *url.URL) (include bool, primary bool)
+}
@@ -0,0 +1,91 @@
+package scope_test
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+
+	"sitemap/scope"
+)
+
+func mustParse(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", raw, err)
+	}
+	return u
+}
+
+func TestSameHost(t *testing.T) {
+	start := mustParse(t, "https://example.com/")
+	s := scope.NewSameHost(start)
+
+	if include, primary := s.Check(mustParse(t, "https://example.com/page")); !include || !primary {
+		t.Errorf("expected same host to be in scope")
+	}
+	if include, _ := s.Check(mustParse(t, "https://blog.example.com/page")); include {
+		t.Errorf("expected a different host to be out of scope")
+	}
+}
+
+func TestSameRegisteredDomain(t *testing.T) {
+	start := mustParse(t, "https://www.example.com/")
+	s, err := scope.NewSameRegisteredDomain(start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if include, _ := s.Check(mustParse(t, "https://blog.example.com/page")); !include {
+		t.Errorf("expected a sibling subdomain to be in scope")
+	}
+	if include, _ := s.Check(mustParse(t, "https://example.org/page")); include {
+		t.Errorf("expected a different registered domain to be out of scope")
+	}
+}
+
+func TestPathPrefix(t *testing.T) {
+	s := &scope.PathPrefix{Prefix: "/docs/"}
+
+	if include, _ := s.Check(mustParse(t, "https://example.com/docs/intro")); !include {
+		t.Errorf("expected a path under the prefix to be in scope")
+	}
+	if include, _ := s.Check(mustParse(t, "https://example.com/blog/post")); include {
+		t.Errorf("expected a path outside the prefix to be out of scope")
+	}
+}
+
+func TestRegexAllowAndDeny(t *testing.T) {
+	allow := &scope.RegexAllow{Patterns: []*regexp.Regexp{regexp.MustCompile(`/docs/`)}}
+	deny := &scope.RegexDeny{Patterns: []*regexp.Regexp{regexp.MustCompile(`/docs/internal/`)}}
+
+	combined := scope.All{allow, deny}
+
+	if include, _ := combined.Check(mustParse(t, "https://example.com/docs/intro")); !include {
+		t.Errorf("expected an allowed, non-denied URL to be in scope")
+	}
+	if include, _ := combined.Check(mustParse(t, "https://example.com/docs/internal/secret")); include {
+		t.Errorf("expected a denied URL to be out of scope even though it matches allow")
+	}
+	if include, _ := combined.Check(mustParse(t, "https://example.com/blog/post")); include {
+		t.Errorf("expected a URL not matching allow to be out of scope")
+	}
+}
+
+func TestAllAndAnyCombinators(t *testing.T) {
+	host := scope.NewSameHost(mustParse(t, "https://example.com/"))
+	prefix := &scope.PathPrefix{Prefix: "/docs/"}
+
+	all := scope.All{host, prefix}
+	if include, _ := all.Check(mustParse(t, "https://example.com/docs/intro")); !include {
+		t.Errorf("expected All to require every child scope to match")
+	}
+	if include, _ := all.Check(mustParse(t, "https://example.com/blog/post")); include {
+		t.Errorf("expected All to reject a URL failing one child scope")
+	}
+
+	any := scope.Any{host, prefix}
+	if include, _ := any.Check(mustParse(t, "https://other.com/docs/intro")); !include {
+		t.Errorf("expected Any to accept a URL matching at least one child scope")
+	}
+}